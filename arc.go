@@ -0,0 +1,304 @@
+/* ARC (Adaptive Replacement Cache) кеширование
+
+в отличие от обычного LRU, ARC хранит четыре списка:
+ - t1 - элементы, запрошенные один раз (недавние)
+ - t2 - элементы, запрошенные два и более раз (частые)
+ - b1 - "призрачный" список ключей, недавно вытесненных из t1 (без значений)
+ - b2 - "призрачный" список ключей, недавно вытесненных из t2 (без значений)
+
+p - целевой размер t1 (0 <= p <= capacity), который адаптивно меняется:
+при попадании в b1 (ключ раньше был в t1, но вытеснен) p увеличивается,
+при попадании в b2 (ключ раньше был в t2, но вытеснен) p уменьшается.
+таким образом кеш сам подстраивается под то, что важнее - недавность
+или частота обращений.
+
+TTL обрабатывается так же, как и в обычном cache - каждый элемент t1/t2
+хранит свой дедлайн, а горутина deleteByTTL раз в tickerTime проверяет
+оба списка и удаляет устаревшие элементы. призрачные списки значений не
+хранят и поэтому TTL не подвержены.
+*/
+
+package cache
+
+import (
+	"container/list"
+	"errors"
+	"sync"
+	"time"
+)
+
+type arcCache struct {
+	capacity int
+	p        int
+
+	t1 *list.List
+	t2 *list.List
+	b1 *list.List
+	b2 *list.List
+
+	t1Items map[interface{}]*list.Element
+	t2Items map[interface{}]*list.Element
+	b1Items map[interface{}]*list.Element
+	b2Items map[interface{}]*list.Element
+
+	mu   sync.Mutex
+	done chan struct{}
+}
+
+func NewARCCache(cap int) (*arcCache, error) {
+	if cap <= 0 {
+		return nil, errors.New("invalid capacity")
+	}
+	c := &arcCache{
+		capacity: cap,
+		t1:       list.New(),
+		t2:       list.New(),
+		b1:       list.New(),
+		b2:       list.New(),
+		t1Items:  make(map[interface{}]*list.Element),
+		t2Items:  make(map[interface{}]*list.Element),
+		b1Items:  make(map[interface{}]*list.Element),
+		b2Items:  make(map[interface{}]*list.Element),
+		done:     make(chan struct{}),
+	}
+	go c.deleteByTTL()
+	return c, nil
+}
+
+func (c *arcCache) Cap() int {
+	return c.capacity
+}
+
+func (c *arcCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.p = 0
+	c.t1.Init()
+	c.t2.Init()
+	c.b1.Init()
+	c.b2.Init()
+	c.t1Items = make(map[interface{}]*list.Element)
+	c.t2Items = make(map[interface{}]*list.Element)
+	c.b1Items = make(map[interface{}]*list.Element)
+	c.b2Items = make(map[interface{}]*list.Element)
+}
+
+// StopTTLRemoval завершает работу горутины, удаляющей элементы с истекшим ttl
+// должен вызываться в конце работы с кешем
+func (c *arcCache) StopTTLRemoval() {
+	c.done <- struct{}{}
+}
+
+func (c *arcCache) deleteByTTL() {
+	ticker := time.NewTicker(1 * tickerTime)
+	for {
+		select {
+		case <-ticker.C:
+			c.mu.Lock()
+			c.evictExpired(c.t1, c.t1Items)
+			c.evictExpired(c.t2, c.t2Items)
+			c.mu.Unlock()
+		case <-c.done:
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+// evictExpired просматривает список l и удаляет из него и из items все
+// элементы с истекшим ttl. вызывающий должен держать мьютекс
+func (c *arcCache) evictExpired(l *list.List, items map[interface{}]*list.Element) {
+	elem := l.Front()
+	for elem != nil {
+		next := elem.Next()
+		entry := elem.Value.(*item)
+		if !entry.ttl.IsZero() && entry.ttl.Before(time.Now()) {
+			l.Remove(elem)
+			delete(items, entry.key)
+		}
+		elem = next
+	}
+}
+
+func (c *arcCache) Add(key, value interface{}) {
+	c.addWithTTL(key, value, time.Time{})
+}
+
+func (c *arcCache) AddWithTTL(key, value interface{}, ttl time.Duration) {
+	c.addWithTTL(key, value, time.Now().Add(ttl))
+}
+
+func (c *arcCache) addWithTTL(key, value interface{}, ttl time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.t1Items[key]; ok {
+		entry := elem.Value.(*item)
+		entry.value = value
+		entry.ttl = ttl
+		c.t1.Remove(elem)
+		delete(c.t1Items, key)
+		c.t2Items[key] = c.t2.PushFront(entry)
+		return
+	}
+
+	if elem, ok := c.t2Items[key]; ok {
+		entry := elem.Value.(*item)
+		entry.value = value
+		entry.ttl = ttl
+		c.t2.MoveToFront(elem)
+		return
+	}
+
+	if elem, ok := c.b1Items[key]; ok {
+		delta := 1
+		if c.b1.Len() > 0 {
+			if d := c.b2.Len() / c.b1.Len(); d > delta {
+				delta = d
+			}
+		}
+		c.p += delta
+		if c.p > c.capacity {
+			c.p = c.capacity
+		}
+		c.replace(false)
+		c.b1.Remove(elem)
+		delete(c.b1Items, key)
+		entry := &item{key: key, value: value, ttl: ttl}
+		c.t2Items[key] = c.t2.PushFront(entry)
+		return
+	}
+
+	if elem, ok := c.b2Items[key]; ok {
+		delta := 1
+		if c.b2.Len() > 0 {
+			if d := c.b1.Len() / c.b2.Len(); d > delta {
+				delta = d
+			}
+		}
+		c.p -= delta
+		if c.p < 0 {
+			c.p = 0
+		}
+		c.replace(true)
+		c.b2.Remove(elem)
+		delete(c.b2Items, key)
+		entry := &item{key: key, value: value, ttl: ttl}
+		c.t2Items[key] = c.t2.PushFront(entry)
+		return
+	}
+
+	// ключ не встречался ни в одном из списков
+	if c.t1.Len()+c.b1.Len() == c.capacity {
+		if c.t1.Len() < c.capacity {
+			c.evictGhostLRU(c.b1, c.b1Items)
+			c.replace(false)
+		} else {
+			c.evictLRU(c.t1, c.t1Items, c.b1, c.b1Items)
+		}
+	} else if c.t1.Len()+c.t2.Len()+c.b1.Len()+c.b2.Len() >= c.capacity {
+		if c.t1.Len()+c.t2.Len()+c.b1.Len()+c.b2.Len() == 2*c.capacity {
+			c.evictGhostLRU(c.b2, c.b2Items)
+		}
+		c.replace(false)
+	}
+
+	entry := &item{key: key, value: value, ttl: ttl}
+	c.t1Items[key] = c.t1.PushFront(entry)
+}
+
+// replace вытесняет LRU-элемент из t1 в b1, либо из t2 в b2, согласно
+// текущему значению p. inB2 сообщает, что вытесняемый ключ только что был
+// найден в b2 (используется для правила "|T1| == p").
+func (c *arcCache) replace(inB2 bool) {
+	if c.t1.Len() > 0 && (c.t1.Len() > c.p || (c.t1.Len() == c.p && inB2)) {
+		c.evictLRU(c.t1, c.t1Items, c.b1, c.b1Items)
+		return
+	}
+	if c.t2.Len() > 0 {
+		c.evictLRU(c.t2, c.t2Items, c.b2, c.b2Items)
+	}
+}
+
+// evictLRU удаляет LRU-элемент списка from и переносит его ключ (без
+// значения) в ghost-список to, ограничивая его размер capacity
+func (c *arcCache) evictLRU(from *list.List, fromItems map[interface{}]*list.Element, to *list.List, toItems map[interface{}]*list.Element) {
+	elem := from.Back()
+	if elem == nil {
+		return
+	}
+	entry := elem.Value.(*item)
+	from.Remove(elem)
+	delete(fromItems, entry.key)
+
+	toItems[entry.key] = to.PushFront(entry.key)
+	if to.Len() > c.capacity {
+		c.evictGhostLRU(to, toItems)
+	}
+}
+
+func (c *arcCache) evictGhostLRU(l *list.List, items map[interface{}]*list.Element) {
+	elem := l.Back()
+	if elem == nil {
+		return
+	}
+	l.Remove(elem)
+	delete(items, elem.Value)
+}
+
+func (c *arcCache) Get(key interface{}) (value interface{}, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, found := c.t1Items[key]; found {
+		entry := elem.Value.(*item)
+		if !entry.ttl.IsZero() && entry.ttl.Before(time.Now()) {
+			c.t1.Remove(elem)
+			delete(c.t1Items, key)
+			return nil, false
+		}
+		c.t1.Remove(elem)
+		delete(c.t1Items, key)
+		c.t2Items[key] = c.t2.PushFront(entry)
+		return entry.value, true
+	}
+
+	if elem, found := c.t2Items[key]; found {
+		entry := elem.Value.(*item)
+		if !entry.ttl.IsZero() && entry.ttl.Before(time.Now()) {
+			c.t2.Remove(elem)
+			delete(c.t2Items, key)
+			return nil, false
+		}
+		c.t2.MoveToFront(elem)
+		return entry.value, true
+	}
+
+	return nil, false
+}
+
+func (c *arcCache) Remove(key interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.t1Items[key]; ok {
+		c.t1.Remove(elem)
+		delete(c.t1Items, key)
+		return
+	}
+	if elem, ok := c.t2Items[key]; ok {
+		c.t2.Remove(elem)
+		delete(c.t2Items, key)
+		return
+	}
+	if elem, ok := c.b1Items[key]; ok {
+		c.b1.Remove(elem)
+		delete(c.b1Items, key)
+		return
+	}
+	if elem, ok := c.b2Items[key]; ok {
+		c.b2.Remove(elem)
+		delete(c.b2Items, key)
+	}
+}
@@ -0,0 +1,141 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestARCCapacity(t *testing.T) {
+	_, err := NewARCCache(0)
+	if err.Error() != "invalid capacity" {
+		t.Error("expected to return 'invalid capacity'")
+	}
+
+	cache, err := NewARCCache(5)
+	if err != nil {
+		t.Error(err)
+	}
+	defer cache.StopTTLRemoval()
+
+	if cap := cache.Cap(); cap != 5 {
+		t.Errorf("expected capacity 5, but got %d", cap)
+	}
+}
+
+func TestARCAddGet(t *testing.T) {
+	cache, err := NewARCCache(2)
+	if err != nil {
+		t.Error(err)
+	}
+	defer cache.StopTTLRemoval()
+
+	cache.Add(1, "hi")
+	cache.Add(2, "there")
+
+	res, ok := cache.Get(1)
+	if res != "hi" || !ok {
+		t.Errorf("expected 'hi', true but got %s, %t", res, ok)
+	}
+
+	res, ok = cache.Get(3)
+	if ok {
+		t.Errorf("expected no value, but got %s, %t", res, ok)
+	}
+}
+
+func TestARCPromoteToT2(t *testing.T) {
+	cache, err := NewARCCache(2)
+	if err != nil {
+		t.Error(err)
+	}
+	defer cache.StopTTLRemoval()
+
+	cache.Add("A", 1)
+	// повторное обращение должно перевести "A" из t1 в t2
+	cache.Get("A")
+
+	if _, ok := cache.t1Items["A"]; ok {
+		t.Error("expected 'A' to be promoted out of t1")
+	}
+	if _, ok := cache.t2Items["A"]; !ok {
+		t.Error("expected 'A' to be present in t2")
+	}
+}
+
+func TestARCGhostHitIncreasesP(t *testing.T) {
+	cache, err := NewARCCache(2)
+	if err != nil {
+		t.Error(err)
+	}
+	defer cache.StopTTLRemoval()
+
+	cache.Add("A", 1)
+	cache.Add("B", 2)
+	cache.Add("C", 3) // вытесняет "A" из t1 в b1
+
+	if _, ok := cache.b1Items["A"]; !ok {
+		t.Fatal("expected 'A' to be a ghost entry in b1")
+	}
+
+	pBefore := cache.p
+	cache.Add("A", 4) // попадание в b1 должно увеличить p и вернуть "A" в t2
+	if cache.p <= pBefore {
+		t.Errorf("expected p to increase after b1 hit, got %d -> %d", pBefore, cache.p)
+	}
+
+	res, ok := cache.Get("A")
+	if res != 4 || !ok {
+		t.Errorf("expected 4, true but got %v, %t", res, ok)
+	}
+	if _, ok := cache.t2Items["A"]; !ok {
+		t.Error("expected 'A' to be reinserted into t2")
+	}
+}
+
+func TestARCRemove(t *testing.T) {
+	cache, err := NewARCCache(2)
+	if err != nil {
+		t.Error(err)
+	}
+	defer cache.StopTTLRemoval()
+
+	cache.Add(1, "hi")
+	cache.Remove(1)
+
+	res, ok := cache.Get(1)
+	if ok {
+		t.Errorf("expected the value to be deleted, but got %v, %t", res, ok)
+	}
+}
+
+func TestARCTTLRemoval(t *testing.T) {
+	cache, err := NewARCCache(2)
+	if err != nil {
+		t.Error(err)
+	}
+	defer cache.StopTTLRemoval()
+
+	cache.AddWithTTL(1, "A", 1*time.Millisecond)
+	time.Sleep(2 * time.Second)
+
+	res, ok := cache.Get(1)
+	if ok {
+		t.Errorf("expected element to be removed by now, but got %v, %t", res, ok)
+	}
+}
+
+func TestARCClear(t *testing.T) {
+	cache, err := NewARCCache(2)
+	if err != nil {
+		t.Error(err)
+	}
+	defer cache.StopTTLRemoval()
+
+	cache.Add(1, "hi")
+	cache.Add(2, "there")
+	cache.Clear()
+
+	if _, ok := cache.Get(1); ok {
+		t.Error("expected cache to be empty after Clear")
+	}
+}
@@ -8,19 +8,7 @@ import (
 // метод чтобы получить все значения, но при этом не поднимать их в приоритете
 // и чтобы они не удалялись в get
 func getAllItems(c *cache) ([]interface{}, []interface{}) {
-	keys := make([]interface{}, 0)
-	values := make([]interface{}, 0)
-
-	elem := c.list.Front()
-
-	for elem != nil {
-		next := elem.Next()
-		keys = append(keys, elem.Value.(*item).key)
-		values = append(values, elem.Value.(*item).value)
-		elem = next
-	}
-
-	return keys, values
+	return c.typed.itemsSnapshot()
 }
 
 func TestCapacity(t *testing.T) {
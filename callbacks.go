@@ -0,0 +1,91 @@
+/* колбэки на вытеснение и вставку элементов
+
+Cache[K, V] может уведомлять внешний код о том, что элемент был вытеснен
+или добавлен - это нужно, например, чтобы построить поверх кеша
+write-through/write-back слой, синхронизирующий его с базой данных.
+
+OnEvicted/OnInserted - самый простой способ подписаться на одно событие.
+Subscribe/Unsubscribe - более общий механизм, позволяющий держать сразу
+несколько слушателей и отписывать их по id, когда они больше не нужны.
+
+колбэки всегда вызываются уже после того, как мьютекс кеша отпущен, -
+иначе слушатель, повторно обратившийся к этому же кешу (например
+Get/Add изнутри OnEvicted), привёл бы к дедлоку.
+*/
+
+package cache
+
+// EvictionReason объясняет, почему элемент покинул кеш
+type EvictionReason int
+
+const (
+	EvictionReasonDeleted EvictionReason = iota
+	EvictionReasonCapacityReached
+	EvictionReasonExpired
+)
+
+// Subscribe добавляет слушателя вытеснений и возвращает id, по которому
+// его можно будет отписать через Unsubscribe
+func (c *Cache[K, V]) Subscribe(fn func(key K, value V, reason EvictionReason)) int {
+	c.listenersMu.Lock()
+	defer c.listenersMu.Unlock()
+
+	id := c.nextListenerID
+	c.nextListenerID++
+	c.evictListeners[id] = fn
+	return id
+}
+
+// Unsubscribe отписывает слушателя с данным id, будь то слушатель
+// вытеснений или вставок
+func (c *Cache[K, V]) Unsubscribe(id int) {
+	c.listenersMu.Lock()
+	defer c.listenersMu.Unlock()
+
+	delete(c.evictListeners, id)
+	delete(c.insertListeners, id)
+}
+
+// OnEvicted - короткий путь для Subscribe, когда отписываться не нужно
+func (c *Cache[K, V]) OnEvicted(fn func(key K, value V, reason EvictionReason)) {
+	c.Subscribe(fn)
+}
+
+// OnInserted подписывает слушателя на вставку новых элементов (срабатывает
+// только на новый ключ, не на обновление существующего) и возвращает id
+// для Unsubscribe
+func (c *Cache[K, V]) OnInserted(fn func(key K, value V)) int {
+	c.listenersMu.Lock()
+	defer c.listenersMu.Unlock()
+
+	id := c.nextListenerID
+	c.nextListenerID++
+	c.insertListeners[id] = fn
+	return id
+}
+
+func (c *Cache[K, V]) dispatchEviction(key K, value V, reason EvictionReason) {
+	c.listenersMu.Lock()
+	fns := make([]func(key K, value V, reason EvictionReason), 0, len(c.evictListeners))
+	for _, fn := range c.evictListeners {
+		fns = append(fns, fn)
+	}
+	c.listenersMu.Unlock()
+
+	for _, fn := range fns {
+		fn(key, value, reason)
+	}
+}
+
+func (c *Cache[K, V]) dispatchInsertion(key K, value V) {
+	c.listenersMu.Lock()
+	fns := make([]func(key K, value V), 0, len(c.insertListeners))
+	for _, fn := range c.insertListeners {
+		fns = append(fns, fn)
+	}
+	c.listenersMu.Unlock()
+
+	for _, fn := range fns {
+		fn(key, value)
+	}
+}
@@ -0,0 +1,144 @@
+package cache
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestOnEvictedCapacityReached(t *testing.T) {
+	c, err := New[string, int](1)
+	if err != nil {
+		t.Error(err)
+	}
+	defer c.StopTTLRemoval()
+
+	var mu sync.Mutex
+	var reason EvictionReason
+	var gotKey string
+	done := make(chan struct{})
+
+	c.OnEvicted(func(key string, value int, r EvictionReason) {
+		mu.Lock()
+		gotKey, reason = key, r
+		mu.Unlock()
+		close(done)
+	})
+
+	c.Add("a", 1)
+	c.Add("b", 2) // вытесняет "a" по capacity
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected eviction callback to fire")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if gotKey != "a" || reason != EvictionReasonCapacityReached {
+		t.Errorf("expected ('a', CapacityReached), got (%q, %d)", gotKey, reason)
+	}
+}
+
+func TestOnEvictedRemove(t *testing.T) {
+	c, err := New[string, int](2)
+	if err != nil {
+		t.Error(err)
+	}
+	defer c.StopTTLRemoval()
+
+	done := make(chan EvictionReason, 1)
+	c.OnEvicted(func(key string, value int, r EvictionReason) {
+		done <- r
+	})
+
+	c.Add("a", 1)
+	c.Remove("a")
+
+	select {
+	case r := <-done:
+		if r != EvictionReasonDeleted {
+			t.Errorf("expected EvictionReasonDeleted, got %d", r)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected eviction callback to fire")
+	}
+}
+
+func TestOnEvictedExpired(t *testing.T) {
+	c, err := New[string, int](2)
+	if err != nil {
+		t.Error(err)
+	}
+	defer c.StopTTLRemoval()
+
+	done := make(chan EvictionReason, 1)
+	c.OnEvicted(func(key string, value int, r EvictionReason) {
+		done <- r
+	})
+
+	c.AddWithTTL("a", 1, 1*time.Millisecond)
+
+	select {
+	case r := <-done:
+		if r != EvictionReasonExpired {
+			t.Errorf("expected EvictionReasonExpired, got %d", r)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected eviction callback to fire for expired ttl")
+	}
+}
+
+func TestOnInserted(t *testing.T) {
+	c, err := New[string, int](2)
+	if err != nil {
+		t.Error(err)
+	}
+	defer c.StopTTLRemoval()
+
+	inserts := make(chan string, 2)
+	c.OnInserted(func(key string, value int) {
+		inserts <- key
+	})
+
+	c.Add("a", 1)
+	c.Add("a", 2) // обновление существующего ключа не должно считаться вставкой
+
+	select {
+	case key := <-inserts:
+		if key != "a" {
+			t.Errorf("expected 'a', got %q", key)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected insertion callback to fire")
+	}
+
+	select {
+	case key := <-inserts:
+		t.Errorf("did not expect a second insertion callback, got %q", key)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestUnsubscribeStopsCallback(t *testing.T) {
+	c, err := New[string, int](2)
+	if err != nil {
+		t.Error(err)
+	}
+	defer c.StopTTLRemoval()
+
+	called := false
+	id := c.Subscribe(func(key string, value int, r EvictionReason) {
+		called = true
+	})
+	c.Unsubscribe(id)
+
+	c.Add("a", 1)
+	c.Remove("a")
+	time.Sleep(100 * time.Millisecond)
+
+	if called {
+		t.Error("expected unsubscribed listener not to be called")
+	}
+}
@@ -0,0 +1,271 @@
+/* типизированный LRU кеш на дженериках
+
+Cache[K, V] реализует ту же семантику LRU+TTL, что и обычный cache, но
+без боксинга в interface{}: ключи и значения хранятся в своих
+настоящих типах, а двусвязный список собран вручную из узлов node[K, V]
+вместо container/list, так что на горячем пути (Add/Get) не нужно делать
+type assertion elem.Value.(*item) и не возникает лишних аллокаций под
+interface{}.
+
+список хранится с двумя узлами-пустышками (head и tail): head.next -
+самый недавно использованный элемент, tail.prev - самый старый. это
+избавляет Add/Get/Remove от проверок на nil на границах списка.
+*/
+
+package cache
+
+import (
+	"container/list"
+	"errors"
+	"sync"
+	"time"
+)
+
+type node[K comparable, V any] struct {
+	key        K
+	value      V
+	ttl        time.Time
+	wheelSlot  int
+	wheelElem  *list.Element
+	prev, next *node[K, V]
+}
+
+type Cache[K comparable, V any] struct {
+	capacity int
+	items    map[K]*node[K, V]
+	head     *node[K, V]
+	tail     *node[K, V]
+	wheel    *timingWheel[K]
+	mu       sync.Mutex
+	done     chan struct{}
+
+	listenersMu     sync.Mutex
+	nextListenerID  int
+	evictListeners  map[int]func(key K, value V, reason EvictionReason)
+	insertListeners map[int]func(key K, value V)
+
+	loadsMu sync.Mutex
+	loads   map[K]*call[K, V]
+}
+
+func New[K comparable, V any](cap int) (*Cache[K, V], error) {
+	if cap <= 0 {
+		return nil, errors.New("invalid capacity")
+	}
+
+	head := &node[K, V]{}
+	tail := &node[K, V]{}
+	head.next = tail
+	tail.prev = head
+
+	c := &Cache[K, V]{
+		capacity:        cap,
+		items:           make(map[K]*node[K, V]),
+		head:            head,
+		tail:            tail,
+		wheel:           newTimingWheel[K](wheelSlots, tickerTime),
+		done:            make(chan struct{}),
+		evictListeners:  make(map[int]func(key K, value V, reason EvictionReason)),
+		insertListeners: make(map[int]func(key K, value V)),
+		loads:           make(map[K]*call[K, V]),
+	}
+	go c.runTimingWheel()
+	return c, nil
+}
+
+func (c *Cache[K, V]) unlink(n *node[K, V]) {
+	n.prev.next = n.next
+	n.next.prev = n.prev
+}
+
+func (c *Cache[K, V]) pushFront(n *node[K, V]) {
+	n.next = c.head.next
+	n.prev = c.head
+	c.head.next.prev = n
+	c.head.next = n
+}
+
+func (c *Cache[K, V]) moveToFront(n *node[K, V]) {
+	c.unlink(n)
+	c.pushFront(n)
+}
+
+func (c *Cache[K, V]) back() *node[K, V] {
+	if c.tail.prev == c.head {
+		return nil
+	}
+	return c.tail.prev
+}
+
+// runTimingWheel раз в tickerTime проворачивает колесо на одну корзину и
+// удаляет элементы, для которых наступил срок истечения. в отличие от
+// старого полного скана, здесь проверяется только содержимое одной
+// корзины, а не все элементы кеша
+func (c *Cache[K, V]) runTimingWheel() {
+	ticker := time.NewTicker(c.wheel.tick)
+	for {
+		select {
+		case <-ticker.C:
+			var expired []*node[K, V]
+
+			c.mu.Lock()
+			for _, key := range c.wheel.advance() {
+				if n, ok := c.items[key]; ok {
+					c.deleteNode(n)
+					expired = append(expired, n)
+				}
+			}
+			c.mu.Unlock()
+
+			for _, n := range expired {
+				c.dispatchEviction(n.key, n.value, EvictionReasonExpired)
+			}
+		case <-c.done:
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+// непотокобезопасное удаление узла, должен использоваться мьютекс перед вызовом
+func (c *Cache[K, V]) deleteNode(n *node[K, V]) {
+	c.unlink(n)
+	delete(c.items, n.key)
+	c.wheel.cancel(n.wheelSlot, n.wheelElem)
+	n.wheelElem = nil
+}
+
+// scheduleTTL отменяет ранее запланированное истечение узла (если было) и
+// планирует новое по ttl. ttl <= 0 означает, что элемент не истекает
+func (c *Cache[K, V]) scheduleTTL(n *node[K, V], ttl time.Duration) {
+	c.wheel.cancel(n.wheelSlot, n.wheelElem)
+	n.wheelElem = nil
+
+	if ttl <= 0 {
+		n.ttl = time.Time{}
+		return
+	}
+	n.ttl = time.Now().Add(ttl)
+	n.wheelSlot, n.wheelElem = c.wheel.schedule(n.key, ttl)
+}
+
+// StopTTLRemoval завершает работу горутины, удаляющей элементы с истекшим ttl
+// должен вызываться в конце работы с кешем
+func (c *Cache[K, V]) StopTTLRemoval() {
+	c.done <- struct{}{}
+}
+
+func (c *Cache[K, V]) Cap() int {
+	return c.capacity
+}
+
+func (c *Cache[K, V]) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.items = make(map[K]*node[K, V])
+	c.head.next = c.tail
+	c.tail.prev = c.head
+}
+
+func (c *Cache[K, V]) Add(key K, value V) {
+	c.addWithTTL(key, value, 0)
+}
+
+func (c *Cache[K, V]) AddWithTTL(key K, value V, ttl time.Duration) {
+	c.addWithTTL(key, value, jitterTTL(ttl))
+}
+
+func (c *Cache[K, V]) addWithTTL(key K, value V, ttl time.Duration) {
+	c.mu.Lock()
+
+	var evicted *node[K, V]
+	if n, ok := c.items[key]; ok {
+		n.value = value
+		c.scheduleTTL(n, ttl)
+		c.moveToFront(n)
+		c.mu.Unlock()
+		return
+	}
+
+	if len(c.items) >= c.capacity {
+		evicted = c.deleteLRU()
+	}
+	n := &node[K, V]{key: key, value: value}
+	c.scheduleTTL(n, ttl)
+	c.pushFront(n)
+	c.items[key] = n
+
+	c.mu.Unlock()
+
+	if evicted != nil {
+		c.dispatchEviction(evicted.key, evicted.value, EvictionReasonCapacityReached)
+	}
+	c.dispatchInsertion(key, value)
+}
+
+// deleteLRU вытесняет самый старый элемент и возвращает его узел, либо nil
+// если кеш пуст. должен вызываться под мьютексом
+func (c *Cache[K, V]) deleteLRU() *node[K, V] {
+	n := c.back()
+	if n != nil {
+		c.deleteNode(n)
+	}
+	return n
+}
+
+func (c *Cache[K, V]) Get(key K) (value V, ok bool) {
+	c.mu.Lock()
+
+	n, found := c.items[key]
+	if !found {
+		c.mu.Unlock()
+		var zero V
+		return zero, false
+	}
+
+	expired := !n.ttl.IsZero() && n.ttl.Before(time.Now())
+	if expired {
+		c.deleteNode(n)
+	} else {
+		c.moveToFront(n)
+	}
+	key, val := n.key, n.value
+	c.mu.Unlock()
+
+	if expired {
+		c.dispatchEviction(key, val, EvictionReasonExpired)
+		var zero V
+		return zero, false
+	}
+	return val, true
+}
+
+func (c *Cache[K, V]) Remove(key K) {
+	c.mu.Lock()
+	n, ok := c.items[key]
+	if ok {
+		c.deleteNode(n)
+	}
+	c.mu.Unlock()
+
+	if ok {
+		c.dispatchEviction(n.key, n.value, EvictionReasonDeleted)
+	}
+}
+
+// itemsSnapshot отдаёт ключи и значения от самого недавнего к самому
+// старому элементу, не поднимая их в приоритете. используется в тестах,
+// чтобы заглянуть внутрь кеша не влияя на порядок LRU
+func (c *Cache[K, V]) itemsSnapshot() ([]K, []V) {
+	keys := make([]K, 0, len(c.items))
+	values := make([]V, 0, len(c.items))
+
+	n := c.head.next
+	for n != c.tail {
+		keys = append(keys, n.key)
+		values = append(values, n.value)
+		n = n.next
+	}
+	return keys, values
+}
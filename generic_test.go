@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGenericCapacity(t *testing.T) {
+	_, err := New[string, int](0)
+	if err.Error() != "invalid capacity" {
+		t.Error("expected to return 'invalid capacity'")
+	}
+
+	c, err := New[string, int](5)
+	if err != nil {
+		t.Error(err)
+	}
+	defer c.StopTTLRemoval()
+
+	if cap := c.Cap(); cap != 5 {
+		t.Errorf("expected capacity 5, but got %d", cap)
+	}
+}
+
+func TestGenericAddGet(t *testing.T) {
+	c, err := New[string, int](2)
+	if err != nil {
+		t.Error(err)
+	}
+	defer c.StopTTLRemoval()
+
+	c.Add("a", 1)
+	c.Add("b", 2)
+
+	res, ok := c.Get("a")
+	if res != 1 || !ok {
+		t.Errorf("expected 1, true but got %d, %t", res, ok)
+	}
+
+	res, ok = c.Get("missing")
+	if ok || res != 0 {
+		t.Errorf("expected zero value, false but got %d, %t", res, ok)
+	}
+}
+
+func TestGenericLRU(t *testing.T) {
+	c, err := New[string, int](2)
+	if err != nil {
+		t.Error(err)
+	}
+	defer c.StopTTLRemoval()
+
+	c.Add("a", 1)
+	c.Add("b", 2)
+	c.Add("c", 3) // должен вытеснить "a"
+
+	if _, ok := c.Get("a"); ok {
+		t.Error("expected 'a' to be evicted")
+	}
+	if res, ok := c.Get("c"); res != 3 || !ok {
+		t.Errorf("expected 3, true but got %d, %t", res, ok)
+	}
+}
+
+func TestGenericTTL(t *testing.T) {
+	c, err := New[string, string](2)
+	if err != nil {
+		t.Error(err)
+	}
+	defer c.StopTTLRemoval()
+
+	c.AddWithTTL("a", "hi", 1*time.Millisecond)
+	time.Sleep(2 * time.Millisecond)
+
+	if _, ok := c.Get("a"); ok {
+		t.Error("expected 'a' to have expired")
+	}
+}
+
+func TestGenericRemove(t *testing.T) {
+	c, err := New[int, string](1)
+	if err != nil {
+		t.Error(err)
+	}
+	defer c.StopTTLRemoval()
+
+	c.Add(1, "hi")
+	c.Remove(1)
+
+	if _, ok := c.Get(1); ok {
+		t.Error("expected value to be removed")
+	}
+}
+
+// удостоверяемся, что legacy NewCache по-прежнему ведёт себя так же, как
+// и раньше - он теперь лишь тонкая обёртка над New[interface{}, interface{}]
+func TestLegacyWrapsGeneric(t *testing.T) {
+	c, err := NewCache(2)
+	if err != nil {
+		t.Error(err)
+	}
+	defer c.StopTTLRemoval()
+
+	c.Add(1, "hi")
+	res, ok := c.Get(1)
+	if res != "hi" || !ok {
+		t.Errorf("expected 'hi', true but got %v, %t", res, ok)
+	}
+}
@@ -0,0 +1,107 @@
+/* шардированный кеш
+
+единственный sync.Mutex в cache сериализует все вызовы Get/Add, что
+становится узким местом под нагрузкой из многих горутин. shardedCache
+решает это тем, что делит ключи между N независимыми cache, каждый со
+своим мьютексом и своей TTL-горутиной: конкурентные обращения к разным
+шардам больше не блокируют друг друга.
+
+шард для ключа выбирается через hashKey(key) % shards, поэтому один и
+тот же ключ всегда попадает в один и тот же шард. ёмкость capacity
+делится между шардами поровну (остаток теряется, как обычно при
+целочисленном делении).
+*/
+
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"time"
+)
+
+type shardedCache struct {
+	shards []*cache
+}
+
+func NewShardedCache(cap int, shards int) (*shardedCache, error) {
+	if cap <= 0 {
+		return nil, errors.New("invalid capacity")
+	}
+	if shards <= 0 {
+		return nil, errors.New("invalid shard count")
+	}
+
+	perShard := cap / shards
+	if perShard < 1 {
+		perShard = 1
+	}
+
+	s := &shardedCache{shards: make([]*cache, shards)}
+	for i := range s.shards {
+		c, err := NewCache(perShard)
+		if err != nil {
+			return nil, err
+		}
+		s.shards[i] = c
+	}
+	return s, nil
+}
+
+// hashKey хэширует ключ любого типа через fnv-1a: строки и байты
+// хэшируются напрямую, остальные типы - через их строковое представление
+func hashKey(key interface{}) uint32 {
+	h := fnv.New32a()
+	switch k := key.(type) {
+	case string:
+		h.Write([]byte(k))
+	case []byte:
+		h.Write(k)
+	default:
+		h.Write([]byte(fmt.Sprintf("%v", k)))
+	}
+	return h.Sum32()
+}
+
+func (s *shardedCache) shardFor(key interface{}) *cache {
+	return s.shards[hashKey(key)%uint32(len(s.shards))]
+}
+
+func (s *shardedCache) Cap() int {
+	total := 0
+	for _, shard := range s.shards {
+		total += shard.Cap()
+	}
+	return total
+}
+
+func (s *shardedCache) Clear() {
+	for _, shard := range s.shards {
+		shard.Clear()
+	}
+}
+
+func (s *shardedCache) Add(key, value interface{}) {
+	s.shardFor(key).Add(key, value)
+}
+
+func (s *shardedCache) AddWithTTL(key, value interface{}, ttl time.Duration) {
+	s.shardFor(key).AddWithTTL(key, value, ttl)
+}
+
+func (s *shardedCache) Get(key interface{}) (value interface{}, ok bool) {
+	return s.shardFor(key).Get(key)
+}
+
+func (s *shardedCache) Remove(key interface{}) {
+	s.shardFor(key).Remove(key)
+}
+
+// StopTTLRemoval останавливает TTL-горутины всех шардов
+// должен вызываться в конце работы с кешем
+func (s *shardedCache) StopTTLRemoval() {
+	for _, shard := range s.shards {
+		shard.StopTTLRemoval()
+	}
+}
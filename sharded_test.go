@@ -0,0 +1,131 @@
+package cache
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestShardedCapacity(t *testing.T) {
+	_, err := NewShardedCache(0, 4)
+	if err.Error() != "invalid capacity" {
+		t.Error("expected to return 'invalid capacity'")
+	}
+
+	_, err = NewShardedCache(10, 0)
+	if err.Error() != "invalid shard count" {
+		t.Error("expected to return 'invalid shard count'")
+	}
+
+	c, err := NewShardedCache(100, 4)
+	if err != nil {
+		t.Error(err)
+	}
+	defer c.StopTTLRemoval()
+
+	if cap := c.Cap(); cap != 100 {
+		t.Errorf("expected aggregated capacity 100, but got %d", cap)
+	}
+}
+
+func TestShardedAddGet(t *testing.T) {
+	// generous capacity relative to the number of keys: a sharded cache only
+	// guarantees capacity in aggregate, not per key - fnv-1a can route all
+	// 16 keys into a single shard, so each shard must comfortably hold all
+	// of them on its own for this test to assert zero eviction
+	c, err := NewShardedCache(256, 4)
+	if err != nil {
+		t.Error(err)
+	}
+	defer c.StopTTLRemoval()
+
+	for i := 0; i < 16; i++ {
+		c.Add(fmt.Sprintf("key-%d", i), i)
+	}
+	for i := 0; i < 16; i++ {
+		res, ok := c.Get(fmt.Sprintf("key-%d", i))
+		if res != i || !ok {
+			t.Errorf("expected %d, true but got %v, %t", i, res, ok)
+		}
+	}
+}
+
+func TestShardedSameKeySameShard(t *testing.T) {
+	c, err := NewShardedCache(16, 4)
+	if err != nil {
+		t.Error(err)
+	}
+	defer c.StopTTLRemoval()
+
+	if c.shardFor("same-key") != c.shardFor("same-key") {
+		t.Error("expected the same key to always route to the same shard")
+	}
+}
+
+func TestShardedClear(t *testing.T) {
+	c, err := NewShardedCache(16, 4)
+	if err != nil {
+		t.Error(err)
+	}
+	defer c.StopTTLRemoval()
+
+	c.Add("a", 1)
+	c.Add("b", 2)
+	c.Clear()
+
+	if _, ok := c.Get("a"); ok {
+		t.Error("expected cache to be empty after Clear")
+	}
+}
+
+func TestShardedRemove(t *testing.T) {
+	c, err := NewShardedCache(16, 4)
+	if err != nil {
+		t.Error(err)
+	}
+	defer c.StopTTLRemoval()
+
+	c.Add("a", 1)
+	c.Remove("a")
+
+	if _, ok := c.Get("a"); ok {
+		t.Error("expected value to be removed")
+	}
+}
+
+// benchmarkConcurrentAccess бьёт по кешу из goroutines параллельных
+// горутин, чередуя Add и Get по общему набору ключей
+func benchmarkConcurrentAccess(b *testing.B, c ICache, goroutines int) {
+	b.ResetTimer()
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < b.N; i++ {
+				key := fmt.Sprintf("key-%d", (g+i)%1000)
+				c.Add(key, i)
+				c.Get(key)
+			}
+		}(g)
+	}
+	wg.Wait()
+}
+
+func BenchmarkSingleMutexConcurrent32(b *testing.B) {
+	c, err := NewCache(1000)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer c.StopTTLRemoval()
+	benchmarkConcurrentAccess(b, c, 32)
+}
+
+func BenchmarkShardedConcurrent32(b *testing.B) {
+	c, err := NewShardedCache(1000, 32)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer c.StopTTLRemoval()
+	benchmarkConcurrentAccess(b, c, 32)
+}
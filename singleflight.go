@@ -0,0 +1,63 @@
+/* GetOrLoad - защита от cache stampede
+
+при read-through кеше перед базой данных типичная проблема: на промахе
+кеша множество горутин одновременно идут за одним и тем же ключом в бд,
+хотя достаточно было бы одного запроса. GetOrLoad решает это тем, что
+на промахе запоминает ключ в loads вместе с *call, на котором остальные
+горутины, запросившие тот же ключ, блокируются через WaitGroup - вместо
+того, чтобы тоже вызывать loader.
+
+вставка результата в кеш происходит тем же горутином, что вызвал
+loader, сразу после его завершения - поэтому все ожидающие горутины
+получают согласованный результат, и в кеше не может оказаться двух
+версий значения для одного и того же ключа.
+*/
+
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+type call[K comparable, V any] struct {
+	wg    sync.WaitGroup
+	value V
+	ttl   time.Duration
+	err   error
+}
+
+// GetOrLoad возвращает значение по ключу, а на промахе кеша вызывает
+// loader. если несколько горутин одновременно промахнулись по одному и
+// тому же ключу, loader вызовется только у одной из них - остальные
+// дождутся её результата
+func (c *Cache[K, V]) GetOrLoad(key K, loader func() (V, time.Duration, error)) (V, error) {
+	if value, ok := c.Get(key); ok {
+		return value, nil
+	}
+
+	c.loadsMu.Lock()
+	if in, ok := c.loads[key]; ok {
+		c.loadsMu.Unlock()
+		in.wg.Wait()
+		return in.value, in.err
+	}
+
+	in := &call[K, V]{}
+	in.wg.Add(1)
+	c.loads[key] = in
+	c.loadsMu.Unlock()
+
+	value, ttl, err := loader()
+	in.value, in.ttl, in.err = value, ttl, err
+
+	c.loadsMu.Lock()
+	delete(c.loads, key)
+	c.loadsMu.Unlock()
+	in.wg.Done()
+
+	if err == nil {
+		c.AddWithTTL(key, value, ttl)
+	}
+	return value, err
+}
@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetOrLoadCallsLoaderOnce(t *testing.T) {
+	c, err := New[string, int](2)
+	if err != nil {
+		t.Error(err)
+	}
+	defer c.StopTTLRemoval()
+
+	var calls int32
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+
+	results := make([]int, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			value, err := c.GetOrLoad("a", func() (int, time.Duration, error) {
+				atomic.AddInt32(&calls, 1)
+				time.Sleep(10 * time.Millisecond)
+				return 42, 0, nil
+			})
+			if err != nil {
+				t.Error(err)
+			}
+			results[i] = value
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	if calls != 1 {
+		t.Errorf("expected loader to be called exactly once, got %d", calls)
+	}
+	for i, res := range results {
+		if res != 42 {
+			t.Errorf("goroutine %d: expected 42, got %d", i, res)
+		}
+	}
+}
+
+func TestGetOrLoadUsesCacheOnHit(t *testing.T) {
+	c, err := New[string, int](2)
+	if err != nil {
+		t.Error(err)
+	}
+	defer c.StopTTLRemoval()
+
+	c.Add("a", 1)
+
+	called := false
+	value, err := c.GetOrLoad("a", func() (int, time.Duration, error) {
+		called = true
+		return 0, 0, nil
+	})
+	if err != nil {
+		t.Error(err)
+	}
+	if called {
+		t.Error("expected loader not to be called on a cache hit")
+	}
+	if value != 1 {
+		t.Errorf("expected 1, got %d", value)
+	}
+}
+
+func TestGetOrLoadPropagatesError(t *testing.T) {
+	c, err := New[string, int](2)
+	if err != nil {
+		t.Error(err)
+	}
+	defer c.StopTTLRemoval()
+
+	loaderErr := errors.New("backend unavailable")
+	_, err = c.GetOrLoad("a", func() (int, time.Duration, error) {
+		return 0, 0, loaderErr
+	})
+	if err != loaderErr {
+		t.Errorf("expected loader error to propagate, got %v", err)
+	}
+
+	if _, ok := c.Get("a"); ok {
+		t.Error("expected a failed load not to populate the cache")
+	}
+}
+
+func TestGetOrLoadUsesReturnedTTL(t *testing.T) {
+	c, err := New[string, int](2)
+	if err != nil {
+		t.Error(err)
+	}
+	defer c.StopTTLRemoval()
+
+	_, err = c.GetOrLoad("a", func() (int, time.Duration, error) {
+		return 1, 1 * time.Millisecond, nil
+	})
+	if err != nil {
+		t.Error(err)
+	}
+
+	time.Sleep(2 * time.Millisecond)
+	if _, ok := c.Get("a"); ok {
+		t.Error("expected loaded value to respect the ttl returned by loader")
+	}
+}
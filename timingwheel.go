@@ -0,0 +1,109 @@
+/* хешированное временное колесо для TTL
+
+раньше deleteByTTL раз в секунду блокировала весь кеш и проверяла ttl
+каждого элемента - O(N) на тик независимо от того, сколько элементов
+реально истекает. timingWheel вместо этого раскладывает ключи по
+wheelSlots корзинам по ожидаемому времени истечения: колесо
+проворачивается на одну корзину за тик и смотрит только в неё, так что
+стоимость одного тика - O(k), где k - число элементов, у которых ttl
+истекает именно сейчас.
+
+ttl, не помещающийся в один оборот колеса (duration > wheelSlots*tick),
+хранит round - сколько ещё полных оборотов должно пройти, прежде чем
+элемент в этой корзине можно будет считать истёкшим.
+
+jitterTTL добавляет элементу ±5% к запрошенному ttl, чтобы при массовой
+вставке элементов с одинаковым ttl они не скапливались в одной корзине и
+не истекали все разом ("thundering herd").
+*/
+
+package cache
+
+import (
+	"container/list"
+	"math/rand"
+	"time"
+)
+
+const wheelSlots = 300
+
+type wheelEntry[K comparable] struct {
+	key   K
+	round int
+}
+
+type timingWheel[K comparable] struct {
+	tick    time.Duration
+	slots   []*list.List
+	current int
+}
+
+func newTimingWheel[K comparable](slots int, tick time.Duration) *timingWheel[K] {
+	w := &timingWheel[K]{
+		tick:  tick,
+		slots: make([]*list.List, slots),
+	}
+	for i := range w.slots {
+		w.slots[i] = list.New()
+	}
+	return w
+}
+
+// schedule кладёт key в корзину, соответствующую ttl от текущего момента,
+// и возвращает индекс корзины и элемент списка, которые нужно передать
+// cancel, если ключ будет обновлён или удалён раньше срока
+func (w *timingWheel[K]) schedule(key K, ttl time.Duration) (slot int, elem *list.Element) {
+	ticks := int(ttl / w.tick)
+	if ticks < 1 {
+		ticks = 1
+	}
+
+	slot = (w.current + ticks) % len(w.slots)
+	// round считает число ПОЛНЫХ оборотов колеса после первого попадания
+	// в эту корзину, а не всего оборотов, укладывающихся в ttl - иначе
+	// ttl, кратный wheelSlots*tick, ждал бы один лишний оборот
+	round := (ticks - 1) / len(w.slots)
+	elem = w.slots[slot].PushBack(&wheelEntry[K]{key: key, round: round})
+	return slot, elem
+}
+
+// cancel отменяет ранее запланированное истечение ключа
+func (w *timingWheel[K]) cancel(slot int, elem *list.Element) {
+	if elem == nil {
+		return
+	}
+	w.slots[slot].Remove(elem)
+}
+
+// advance проворачивает колесо на одну корзину и возвращает ключи,
+// для которых наступил срок истечения
+func (w *timingWheel[K]) advance() []K {
+	w.current = (w.current + 1) % len(w.slots)
+	bucket := w.slots[w.current]
+
+	var due []K
+	elem := bucket.Front()
+	for elem != nil {
+		next := elem.Next()
+		entry := elem.Value.(*wheelEntry[K])
+		if entry.round <= 0 {
+			due = append(due, entry.key)
+			bucket.Remove(elem)
+		} else {
+			entry.round--
+		}
+		elem = next
+	}
+	return due
+}
+
+// jitterTTL добавляет к ttl случайное отклонение в пределах ±5%, чтобы
+// множество элементов, добавленных одновременно с одинаковым ttl, не
+// истекали все в одну и ту же корзину колеса
+func jitterTTL(ttl time.Duration) time.Duration {
+	if ttl <= 0 {
+		return ttl
+	}
+	jitter := (rand.Float64()*2 - 1) * 0.05 * float64(ttl)
+	return ttl + time.Duration(jitter)
+}
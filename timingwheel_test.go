@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimingWheelScheduleAndAdvance(t *testing.T) {
+	w := newTimingWheel[string](5, time.Millisecond)
+
+	w.schedule("a", 1*time.Millisecond) // попадёт в следующую корзину
+
+	for i := 0; i < 4; i++ {
+		due := w.advance()
+		if i == 0 {
+			if len(due) != 1 || due[0] != "a" {
+				t.Fatalf("expected ['a'] due on first advance, got %v", due)
+			}
+		} else if len(due) != 0 {
+			t.Errorf("expected no due keys on advance %d, got %v", i, due)
+		}
+	}
+}
+
+func TestTimingWheelCancel(t *testing.T) {
+	w := newTimingWheel[string](5, time.Millisecond)
+
+	slot, elem := w.schedule("a", 1*time.Millisecond)
+	w.cancel(slot, elem)
+
+	due := w.advance()
+	if len(due) != 0 {
+		t.Errorf("expected cancelled key not to fire, got %v", due)
+	}
+}
+
+func TestTimingWheelWraparound(t *testing.T) {
+	w := newTimingWheel[string](3, time.Millisecond)
+
+	// ttl на 2 полных оборота вперёд
+	w.schedule("a", 7*time.Millisecond)
+
+	for i := 0; i < 6; i++ {
+		due := w.advance()
+		if len(due) != 0 {
+			t.Fatalf("expected 'a' not to be due yet at advance %d, got %v", i, due)
+		}
+	}
+	due := w.advance()
+	if len(due) != 1 || due[0] != "a" {
+		t.Errorf("expected ['a'] due after wraparound, got %v", due)
+	}
+}
+
+func TestTimingWheelExactRotationExpiresOnTime(t *testing.T) {
+	w := newTimingWheel[string](3, time.Millisecond)
+
+	// ttl ровно равен одному полному обороту колеса (slots*tick) -
+	// должен сработать ровно на 3-м advance, без лишнего оборота
+	w.schedule("a", 3*time.Millisecond)
+
+	for i := 0; i < 2; i++ {
+		due := w.advance()
+		if len(due) != 0 {
+			t.Fatalf("expected 'a' not to be due yet at advance %d, got %v", i, due)
+		}
+	}
+	due := w.advance()
+	if len(due) != 1 || due[0] != "a" {
+		t.Errorf("expected ['a'] due exactly on the 3rd advance, got %v", due)
+	}
+}
+
+func TestJitterTTLWithinBounds(t *testing.T) {
+	ttl := 100 * time.Millisecond
+	for i := 0; i < 100; i++ {
+		jittered := jitterTTL(ttl)
+		diff := jittered - ttl
+		if diff < -5*time.Millisecond || diff > 5*time.Millisecond {
+			t.Errorf("expected jitter within +/-5%%, got %v for ttl %v", jittered, ttl)
+		}
+	}
+}
+
+func TestJitterTTLZeroUnchanged(t *testing.T) {
+	if jitterTTL(0) != 0 {
+		t.Error("expected zero ttl to stay zero (no expiry)")
+	}
+}
@@ -0,0 +1,276 @@
+/* 2Q кеширование (Johnson/Shasha)
+
+в отличие от обычного LRU, 2Q делит элементы на три списка:
+ - recent (A1in) - элементы, добавленные первый раз, управляется по LRU,
+   размер ограничен recentRatio*capacity
+ - frequent (Am) - элементы, к которым обратились повторно, управляется по LRU
+ - recentEvict (A1out) - "призрачный" список ключей, недавно вытесненных из
+   recent (без значений), размер ограничен ghostRatio*capacity
+
+смысл в том, чтобы не пускать в frequent элементы, к которым обратились
+всего один раз (типичные сканирующие нагрузки), - они сначала проходят
+через recent и только при повторном обращении попадают в frequent.
+
+TTL обрабатывается так же, как и в обычном cache - каждый элемент recent
+и frequent хранит свой дедлайн, а горутина deleteByTTL раз в tickerTime
+проверяет оба списка и удаляет устаревшие элементы.
+*/
+
+package cache
+
+import (
+	"container/list"
+	"errors"
+	"sync"
+	"time"
+)
+
+type twoQueueCache struct {
+	capacity   int
+	recentSize int
+	ghostSize  int
+
+	recent      *list.List
+	frequent    *list.List
+	recentEvict *list.List
+
+	recentItems      map[interface{}]*list.Element
+	frequentItems    map[interface{}]*list.Element
+	recentEvictItems map[interface{}]*list.Element
+
+	mu   sync.Mutex
+	done chan struct{}
+}
+
+func NewTwoQueueCache(cap int, recentRatio, ghostRatio float64) (*twoQueueCache, error) {
+	if cap <= 0 {
+		return nil, errors.New("invalid capacity")
+	}
+	if recentRatio <= 0 || recentRatio > 1 {
+		return nil, errors.New("invalid recent ratio")
+	}
+	if ghostRatio <= 0 || ghostRatio > 1 {
+		return nil, errors.New("invalid ghost ratio")
+	}
+
+	recentSize := int(recentRatio * float64(cap))
+	if recentSize < 1 {
+		recentSize = 1
+	}
+	ghostSize := int(ghostRatio * float64(cap))
+	if ghostSize < 1 {
+		ghostSize = 1
+	}
+
+	c := &twoQueueCache{
+		capacity:         cap,
+		recentSize:       recentSize,
+		ghostSize:        ghostSize,
+		recent:           list.New(),
+		frequent:         list.New(),
+		recentEvict:      list.New(),
+		recentItems:      make(map[interface{}]*list.Element),
+		frequentItems:    make(map[interface{}]*list.Element),
+		recentEvictItems: make(map[interface{}]*list.Element),
+		done:             make(chan struct{}),
+	}
+	go c.deleteByTTL()
+	return c, nil
+}
+
+func (c *twoQueueCache) Cap() int {
+	return c.capacity
+}
+
+func (c *twoQueueCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.recent.Init()
+	c.frequent.Init()
+	c.recentEvict.Init()
+	c.recentItems = make(map[interface{}]*list.Element)
+	c.frequentItems = make(map[interface{}]*list.Element)
+	c.recentEvictItems = make(map[interface{}]*list.Element)
+}
+
+// StopTTLRemoval завершает работу горутины, удаляющей элементы с истекшим ttl
+// должен вызываться в конце работы с кешем
+func (c *twoQueueCache) StopTTLRemoval() {
+	c.done <- struct{}{}
+}
+
+func (c *twoQueueCache) deleteByTTL() {
+	ticker := time.NewTicker(1 * tickerTime)
+	for {
+		select {
+		case <-ticker.C:
+			c.mu.Lock()
+			c.evictExpired(c.recent, c.recentItems)
+			c.evictExpired(c.frequent, c.frequentItems)
+			c.mu.Unlock()
+		case <-c.done:
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+func (c *twoQueueCache) evictExpired(l *list.List, items map[interface{}]*list.Element) {
+	elem := l.Front()
+	for elem != nil {
+		next := elem.Next()
+		entry := elem.Value.(*item)
+		if !entry.ttl.IsZero() && entry.ttl.Before(time.Now()) {
+			l.Remove(elem)
+			delete(items, entry.key)
+		}
+		elem = next
+	}
+}
+
+func (c *twoQueueCache) Add(key, value interface{}) {
+	c.addWithTTL(key, value, time.Time{})
+}
+
+func (c *twoQueueCache) AddWithTTL(key, value interface{}, ttl time.Duration) {
+	c.addWithTTL(key, value, time.Now().Add(ttl))
+}
+
+func (c *twoQueueCache) addWithTTL(key, value interface{}, ttl time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.frequentItems[key]; ok {
+		entry := elem.Value.(*item)
+		entry.value = value
+		entry.ttl = ttl
+		c.frequent.MoveToFront(elem)
+		return
+	}
+
+	if elem, ok := c.recentItems[key]; ok {
+		entry := elem.Value.(*item)
+		entry.value = value
+		entry.ttl = ttl
+		c.recent.Remove(elem)
+		delete(c.recentItems, key)
+		c.insertFrequent(entry)
+		return
+	}
+
+	if elem, ok := c.recentEvictItems[key]; ok {
+		c.recentEvict.Remove(elem)
+		delete(c.recentEvictItems, key)
+		entry := &item{key: key, value: value, ttl: ttl}
+		c.insertFrequent(entry)
+		return
+	}
+
+	// ключ встречается впервые
+	entry := &item{key: key, value: value, ttl: ttl}
+	c.recentItems[key] = c.recent.PushFront(entry)
+	c.evictIfNeeded()
+}
+
+func (c *twoQueueCache) insertFrequent(entry *item) {
+	c.frequentItems[entry.key] = c.frequent.PushFront(entry)
+	c.evictIfNeeded()
+}
+
+// evictOneFromRecent вытесняет LRU-элемент recent (A1in) в recentEvict
+// (A1out, ghost-список без значений, тоже ограниченный ghostSize)
+func (c *twoQueueCache) evictOneFromRecent() {
+	elem := c.recent.Back()
+	entry := elem.Value.(*item)
+	c.recent.Remove(elem)
+	delete(c.recentItems, entry.key)
+
+	c.recentEvictItems[entry.key] = c.recentEvict.PushFront(entry.key)
+	if c.recentEvict.Len() > c.ghostSize {
+		ghost := c.recentEvict.Back()
+		c.recentEvict.Remove(ghost)
+		delete(c.recentEvictItems, ghost.Value)
+	}
+}
+
+// evictOneFromFrequent вытесняет LRU-элемент frequent (Am)
+func (c *twoQueueCache) evictOneFromFrequent() {
+	elem := c.frequent.Back()
+	entry := elem.Value.(*item)
+	c.frequent.Remove(elem)
+	delete(c.frequentItems, entry.key)
+}
+
+// evictIfNeeded вытесняет элементы, пока recent+frequent не уложится в
+// capacity. Жертва выбирается так: пока recent не меньше своего целевого
+// recentSize, вытесняем из неё (Johnson/Shasha - recent должен вытеснять
+// в первую очередь, чтобы не пускать в frequent элементы, встреченные
+// всего один раз), а уже стёкший к recentSize recent уступает место
+// вытеснению из frequent; если frequent пуст, вытеснять больше не из
+// чего, кроме recent, несмотря на то, что она уже не больше recentSize
+func (c *twoQueueCache) evictIfNeeded() {
+	for c.recent.Len()+c.frequent.Len() > c.capacity {
+		switch {
+		case c.recent.Len() >= c.recentSize && c.recent.Len() > 0:
+			c.evictOneFromRecent()
+		case c.frequent.Len() > 0:
+			c.evictOneFromFrequent()
+		case c.recent.Len() > 0:
+			c.evictOneFromRecent()
+		default:
+			return
+		}
+	}
+}
+
+func (c *twoQueueCache) Get(key interface{}) (value interface{}, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, found := c.frequentItems[key]; found {
+		entry := elem.Value.(*item)
+		if !entry.ttl.IsZero() && entry.ttl.Before(time.Now()) {
+			c.frequent.Remove(elem)
+			delete(c.frequentItems, key)
+			return nil, false
+		}
+		c.frequent.MoveToFront(elem)
+		return entry.value, true
+	}
+
+	if elem, found := c.recentItems[key]; found {
+		entry := elem.Value.(*item)
+		if !entry.ttl.IsZero() && entry.ttl.Before(time.Now()) {
+			c.recent.Remove(elem)
+			delete(c.recentItems, key)
+			return nil, false
+		}
+		c.recent.Remove(elem)
+		delete(c.recentItems, key)
+		c.insertFrequent(entry)
+		return entry.value, true
+	}
+
+	return nil, false
+}
+
+func (c *twoQueueCache) Remove(key interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.recentItems[key]; ok {
+		c.recent.Remove(elem)
+		delete(c.recentItems, key)
+		return
+	}
+	if elem, ok := c.frequentItems[key]; ok {
+		c.frequent.Remove(elem)
+		delete(c.frequentItems, key)
+		return
+	}
+	if elem, ok := c.recentEvictItems[key]; ok {
+		c.recentEvict.Remove(elem)
+		delete(c.recentEvictItems, key)
+	}
+}
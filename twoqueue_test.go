@@ -0,0 +1,188 @@
+package cache
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestTwoQueueCapacity(t *testing.T) {
+	_, err := NewTwoQueueCache(0, 0.25, 0.5)
+	if err.Error() != "invalid capacity" {
+		t.Error("expected to return 'invalid capacity'")
+	}
+
+	cache, err := NewTwoQueueCache(5, 0.25, 0.5)
+	if err != nil {
+		t.Error(err)
+	}
+	defer cache.StopTTLRemoval()
+
+	if cap := cache.Cap(); cap != 5 {
+		t.Errorf("expected capacity 5, but got %d", cap)
+	}
+}
+
+func TestTwoQueueAddGet(t *testing.T) {
+	cache, err := NewTwoQueueCache(2, 0.5, 0.5)
+	if err != nil {
+		t.Error(err)
+	}
+	defer cache.StopTTLRemoval()
+
+	cache.Add(1, "hi")
+	cache.Add(2, "there")
+
+	res, ok := cache.Get(1)
+	if res != "hi" || !ok {
+		t.Errorf("expected 'hi', true but got %s, %t", res, ok)
+	}
+
+	res, ok = cache.Get(3)
+	if ok {
+		t.Errorf("expected no value, but got %s, %t", res, ok)
+	}
+}
+
+func TestTwoQueuePromotesOnSecondAccess(t *testing.T) {
+	cache, err := NewTwoQueueCache(2, 0.5, 0.5)
+	if err != nil {
+		t.Error(err)
+	}
+	defer cache.StopTTLRemoval()
+
+	cache.Add("A", 1)
+	if _, ok := cache.recentItems["A"]; !ok {
+		t.Fatal("expected 'A' to be inserted into recent")
+	}
+
+	cache.Get("A")
+	if _, ok := cache.frequentItems["A"]; !ok {
+		t.Error("expected 'A' to be promoted to frequent after second access")
+	}
+}
+
+func TestTwoQueueGhostReinsertGoesToFrequent(t *testing.T) {
+	cache, err := NewTwoQueueCache(1, 0.5, 1)
+	if err != nil {
+		t.Error(err)
+	}
+	defer cache.StopTTLRemoval()
+
+	cache.Add("A", 1) // вытесняется сразу следующим Add
+	cache.Add("B", 2)
+
+	if _, ok := cache.recentEvictItems["A"]; !ok {
+		t.Fatal("expected 'A' to be a ghost entry")
+	}
+
+	cache.Add("A", 3)
+	if _, ok := cache.frequentItems["A"]; !ok {
+		t.Error("expected 'A' to be reinserted directly into frequent")
+	}
+}
+
+func TestTwoQueueRecentSizePreferredEvictionSource(t *testing.T) {
+	cache, err := NewTwoQueueCache(2, 0.5, 1)
+	if err != nil {
+		t.Error(err)
+	}
+	defer cache.StopTTLRemoval()
+
+	// recentSize = 1: "A" попадает в frequent и больше не участвует в
+	// вытеснении по recent, "B" и "C" идут в recent. Как только "C"
+	// переполняет общую capacity, recent уже не меньше своего recentSize,
+	// поэтому вытесняется именно "B" (LRU в recent), а не "A" из frequent -
+	// так recentSize влияет на то, откуда берётся жертва, а не только на то,
+	// что попадает в recent
+	cache.Add("A", 1)
+	cache.Get("A")
+	cache.Add("B", 2)
+	cache.Add("C", 3)
+
+	if _, ok := cache.frequentItems["A"]; !ok {
+		t.Error("expected 'A' to stay in frequent instead of being evicted")
+	}
+	if _, ok := cache.recentEvictItems["B"]; !ok {
+		t.Error("expected 'B' to be evicted from recent into the ghost list")
+	}
+	if _, ok := cache.recentItems["C"]; !ok {
+		t.Error("expected 'C' to remain in recent")
+	}
+}
+
+func TestTwoQueueRemove(t *testing.T) {
+	cache, err := NewTwoQueueCache(2, 0.5, 0.5)
+	if err != nil {
+		t.Error(err)
+	}
+	defer cache.StopTTLRemoval()
+
+	cache.Add(1, "hi")
+	cache.Remove(1)
+
+	res, ok := cache.Get(1)
+	if ok {
+		t.Errorf("expected the value to be deleted, but got %v, %t", res, ok)
+	}
+}
+
+func TestTwoQueueTTLRemoval(t *testing.T) {
+	cache, err := NewTwoQueueCache(2, 0.5, 0.5)
+	if err != nil {
+		t.Error(err)
+	}
+	defer cache.StopTTLRemoval()
+
+	cache.AddWithTTL(1, "A", 1*time.Millisecond)
+	time.Sleep(2 * time.Second)
+
+	res, ok := cache.Get(1)
+	if ok {
+		t.Errorf("expected element to be removed by now, but got %v, %t", res, ok)
+	}
+}
+
+// benchmarkScanThenWorkingSet моделирует нагрузку, где большое
+// последовательное сканирование (каждый ключ встречается один раз)
+// перемежается с обращениями к маленькому "горячему" набору ключей.
+// это типичный сценарий, в котором plain LRU вытесняет горячие элементы
+// сканирующим проходом, а 2Q - нет, т.к. однократно встреченные ключи
+// остаются в recent и не попадают в frequent.
+func benchmarkScanThenWorkingSet(b *testing.B, c ICache, scanSize, hotSize int) {
+	for i := 0; i < hotSize; i++ {
+		c.Add(fmt.Sprintf("hot-%d", i), i)
+	}
+
+	b.ResetTimer()
+	hits := 0
+	for i := 0; i < b.N; i++ {
+		for s := 0; s < scanSize; s++ {
+			c.Add(fmt.Sprintf("scan-%d", s), s)
+		}
+		for h := 0; h < hotSize; h++ {
+			if _, ok := c.Get(fmt.Sprintf("hot-%d", h)); ok {
+				hits++
+			}
+		}
+	}
+	b.ReportMetric(float64(hits)/float64(b.N*hotSize), "hit-ratio")
+}
+
+func BenchmarkLRUScanHeavy(b *testing.B) {
+	c, err := NewCache(50)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer c.StopTTLRemoval()
+	benchmarkScanThenWorkingSet(b, c, 200, 20)
+}
+
+func BenchmarkTwoQueueScanHeavy(b *testing.B) {
+	c, err := NewTwoQueueCache(50, 0.25, 0.5)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer c.StopTTLRemoval()
+	benchmarkScanThenWorkingSet(b, c, 200, 20)
+}